@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+const sourceAPOD = "apod"
+
+const apodURL = "https://api.nasa.gov/planetary/apod"
+
+type apodResponse struct {
+	Title     string `json:"title"`
+	Date      string `json:"date"`
+	URL       string `json:"url"`
+	HDURL     string `json:"hdurl"`
+	MediaType string `json:"media_type"`
+}
+
+// apodSource fetches NASA's Astronomy Picture of the Day.
+type apodSource struct {
+	apiKey string
+}
+
+func (a *apodSource) Fetch(ctx context.Context) (*imageMetadata, io.ReadCloser, error) {
+	v := url.Values{}
+	v.Set("api_key", a.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apodURL+"?"+v.Encode(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var ar apodResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ar); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode JSON response: %w", err)
+	}
+
+	if ar.MediaType != "" && ar.MediaType != "image" {
+		return nil, nil, fmt.Errorf("APOD for %s is a %s, not an image", ar.Date, ar.MediaType)
+	}
+
+	imageURL := ar.HDURL
+	if imageURL == "" {
+		imageURL = ar.URL
+	}
+	if imageURL == "" {
+		return nil, nil, fmt.Errorf("APOD response for %s had no image URL", ar.Date)
+	}
+
+	im := &imageMetadata{
+		URL:       imageURL,
+		Title:     ar.Title,
+		Hash:      "apod-" + ar.Date,
+		Extension: extensionFromURL(imageURL, "jpg"),
+		Source:    sourceAPOD,
+	}
+
+	imgReq, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	imgResp, err := http.DefaultClient.Do(imgReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while downloading image: %w", err)
+	}
+
+	if imgResp.StatusCode != http.StatusOK {
+		imgResp.Body.Close()
+		return nil, nil, fmt.Errorf("unexpected response code: %s", imgResp.Status)
+	}
+
+	return im, imgResp.Body, nil
+}
+
+// extensionFromURL returns the file extension (without the leading dot) of
+// the URL's path, falling back to def when the URL has none.
+func extensionFromURL(rawURL, def string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return def
+	}
+
+	ext := path.Ext(u.Path)
+	if ext == "" {
+		return def
+	}
+
+	return ext[1:]
+}
@@ -0,0 +1,43 @@
+package main
+
+import "net/http"
+
+// authFunc reports whether an incoming request is allowed to proceed. It
+// exists as a pluggable hook so bingdaily's HTTP server can be safely
+// exposed beyond localhost (e.g. on a home LAN) without hard-coding one
+// auth scheme.
+type authFunc func(r *http.Request) bool
+
+// tokenAuth builds an authFunc that accepts either `Authorization: Bearer
+// <token>` or HTTP basic auth with token as the password. An empty token
+// disables auth entirely.
+func tokenAuth(token string) authFunc {
+	if token == "" {
+		return func(r *http.Request) bool { return true }
+	}
+
+	return func(r *http.Request) bool {
+		if auth := r.Header.Get("Authorization"); auth == "Bearer "+token {
+			return true
+		}
+
+		if _, pass, ok := r.BasicAuth(); ok && pass == token {
+			return true
+		}
+
+		return false
+	}
+}
+
+// requireAuth wraps next so it's only invoked when auth(r) passes.
+func requireAuth(auth authFunc, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !auth(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="bingdaily"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const sourceBing = "bing"
+
+const (
+	bingRoot = "https://www.bing.com"
+	bingURL  = bingRoot + "/HPImageArchive.aspx?format=js&n=1&idx=%d"
+
+	// bingMaxIdx is the furthest back into the archive Bing's
+	// HPImageArchive endpoint supports navigating.
+	bingMaxIdx = 7
+)
+
+// bingResponse is a type used for parsing the response from bing
+type bingResponse struct {
+	Images []bingImage `json:"images"`
+}
+
+type bingImage struct {
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Hash      string `json:"hsh"`
+	Copyright string `json:"copyright"`
+}
+
+// bingSource fetches a Bing "Image of the Day". Idx selects how many days
+// back into the archive to look: 0 is today, up to bingMaxIdx days back.
+type bingSource struct {
+	Idx int
+}
+
+func (b *bingSource) Fetch(ctx context.Context) (*imageMetadata, io.ReadCloser, error) {
+	bi, err := getLatestMetadata(ctx, b.Idx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to obtain metadata: %w", err)
+	}
+
+	im := &imageMetadata{
+		URL:       bingRoot + bi.URL,
+		Title:     bi.Title,
+		Hash:      bi.Hash,
+		Extension: "jpg",
+		Source:    sourceBing,
+		Copyright: bi.Copyright,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, im.URL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while downloading image: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, nil, fmt.Errorf("unexpected response code: %s", resp.Status)
+	}
+
+	return im, resp.Body, nil
+}
+
+func getLatestMetadata(ctx context.Context, idx int) (*bingImage, error) {
+	var r bingResponse
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(bingURL, idx), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download metadata: %w", err)
+	}
+
+	defer resp.Body.Close()
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
+	}
+
+	if len(r.Images) == 0 {
+		return nil, errors.New("no images found in JSON response")
+	}
+
+	return &r.Images[0], nil
+}
@@ -1,50 +1,86 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
+	"flag"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
-	"math/rand"
-	"net/http"
 	"os"
-	"os/exec"
-	"os/user"
 	"path"
-	"sort"
-	"strings"
-	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 )
 
-const (
-	bingRoot = "https://www.bing.com"
-	bingURL  = bingRoot + "/HPImageArchive.aspx?format=js&idx=0&n=1"
-	imgDir   = ".bingdaily"
-)
+const imgDir = ".bingdaily"
 
+// imageMetadata describes a single image as returned by an ImageSource.
+// Extension is source-provided since not every source serves JPEGs (e.g.
+// Himawari composites are PNGs).
 type imageMetadata struct {
-	URL   string `json:"url"`
-	Title string `json:"title"`
-	Hash  string `json:"hsh"`
+	URL       string
+	Title     string
+	Hash      string
+	Extension string
+	Source    string
+	Copyright string
 }
 
-// response is a type used for parsing the response from bing
-type response struct {
-	Images []imageMetadata `json:"images"`
-}
+var (
+	backendFlag        = flag.String("backend", "", "wallpaper backend to use (platform-specific; linux: gnome, kde, xfce, sway). Defaults to auto-detect")
+	sourceFlag         = flag.String("source", sourceBing, "image source to use (bing, apod, unsplash, himawari, local, random)")
+	apodKeyFlag        = flag.String("apod-key", "DEMO_KEY", "NASA API key for the apod source")
+	unsplashKeyFlag    = flag.String("unsplash-key", "", "Unsplash access key for the unsplash source")
+	himawariOffsetFlag = flag.Int("himawari-offset", 0, "hours to look back from the latest available Himawari-8 image")
+	localDirFlag       = flag.String("local-dir", "", "directory to pick images from for the local source")
+)
 
 func main() {
-	err := Execute()
+	flag.Parse()
+
+	err := run(flag.Args())
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
+// run dispatches the (non-flag) command line arguments. When --serve is set
+// it starts the HTTP server and the arguments are not looked at. Otherwise,
+// with none, it does
+// the usual one-shot fetch-and-set-wallpaper run; "daemon <day|rand> ..."
+// starts a background daemon; "now", "prev" and "next" are client commands
+// sent to an already-running daemon's control socket.
+func run(args []string) error {
+	if *serveFlag != "" {
+		return runServer(*serveFlag)
+	}
+
+	if len(args) == 0 {
+		return Execute()
+	}
+
+	switch args[0] {
+	case "daemon":
+		if len(args) < 2 {
+			return fmt.Errorf("daemon requires a mode: day or rand")
+		}
+		return runDaemon(args[1], args[2:])
+	case "now", "prev", "next":
+		targetDir, err := defaultTargetDir()
+		if err != nil {
+			return err
+		}
+		socketPath := path.Join(targetDir, controlSocketName)
+		return sendControlCommand(socketPath, args[0])
+	case "list":
+		return runList(args[1:])
+	case "show":
+		return runShow(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
 // Execute is where we actually do our work
 func Execute() error {
 	log.Println("Starting bingdaily run")
@@ -63,21 +99,26 @@ func Execute() error {
 		return fmt.Errorf("failed to make image directory: %w", err)
 	}
 
-	im, err := getLatestMetadata()
+	src, err := newImageSource(*sourceFlag)
 	if err != nil {
-		return fmt.Errorf("failed to obtain metadata: %w", err)
+		return fmt.Errorf("failed to select image source: %w", err)
 	}
 
-	log.Printf("Obtained image metadata: %v\n", im)
-
-	err = downloadImage(targetDir, im)
+	im, err := downloadImage(context.Background(), targetDir, src)
 	if err != nil {
 		return fmt.Errorf("failed to download image: %w", err)
 	}
 
+	log.Printf("Obtained image metadata: %+v\n", im)
+
 	log.Println("Updating background image")
 
-	err = setWallpaper(targetDir)
+	ws, err := NewWallpaperSetter(*backendFlag)
+	if err != nil {
+		return fmt.Errorf("failed to select wallpaper backend: %w", err)
+	}
+
+	err = setWallpaper(targetDir, ws)
 	if err != nil {
 		return fmt.Errorf("failed to set wallpaper: %w", err)
 	}
@@ -85,67 +126,46 @@ func Execute() error {
 	return nil
 }
 
-func getLatestMetadata() (*imageMetadata, error) {
-	var r response
-	resp, err := http.Get(bingURL)
+// downloadImage fetches the image from src, writing it to imageDir unless a
+// file for it already exists, and returns its metadata either way.
+func downloadImage(ctx context.Context, imageDir string, src ImageSource) (*imageMetadata, error) {
+	im, body, err := src.Fetch(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download metadata: %w", err)
-	}
-
-	defer resp.Body.Close()
-	err = json.NewDecoder(resp.Body).Decode(&r)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode JSON response: %w", err)
-	}
-
-	if len(r.Images) == 0 {
-		return nil, errors.New("no images found in JSON response")
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
 	}
+	defer body.Close()
 
-	return &r.Images[0], nil
-}
-
-func downloadImage(imageDir string, im *imageMetadata) error {
-	filename := path.Join(imageDir, im.Hash+".jpg")
+	filename := path.Join(imageDir, im.Hash+"."+im.Extension)
 
 	log.Printf("Checking for existence of file: %s", filename)
 
 	exists, err := imageExists(filename)
 	if err != nil {
-		return fmt.Errorf("unable to determine whether file exists: %v", err)
+		return nil, fmt.Errorf("unable to determine whether file exists: %v", err)
 	}
 
 	if exists {
 		log.Println("Image already exists, no need to download")
-		return nil
-	}
-
-	fullURL := bingRoot + im.URL
-
-	resp, err := http.Get(fullURL)
-	if err != nil {
-		return fmt.Errorf("error while downloading image: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected response code: %s", resp.Status)
+		return im, nil
 	}
 
-	defer resp.Body.Close()
-
 	file, err := os.Create(filename)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return nil, fmt.Errorf("failed to create output file: %w", err)
 	}
 
 	defer file.Close()
 
-	_, err = io.Copy(file, resp.Body)
+	_, err = io.Copy(file, body)
 	if err != nil {
-		return fmt.Errorf("failed to write image to output file: %w", err)
+		return nil, fmt.Errorf("failed to write image to output file: %w", err)
 	}
 
-	return nil
+	if err := recordHistory(imageDir, im); err != nil {
+		return nil, fmt.Errorf("failed to record history entry: %w", err)
+	}
+
+	return im, nil
 }
 
 func imageExists(filename string) (bool, error) {
@@ -155,114 +175,3 @@ func imageExists(filename string) (bool, error) {
 	}
 	return err == nil, err
 }
-
-func setWallpaper(dirname string) error {
-	err := removeOldFiles(dirname)
-	if err != nil {
-		return fmt.Errorf("Failed to remove old files: %v", err)
-	}
-
-	filename, err := chooseImage(dirname)
-	if err != nil {
-		return fmt.Errorf("Failed to choose an image")
-	}
-
-	dbusAddress, err := obtainDbusAddress()
-	if err != nil {
-		return fmt.Errorf("Failed to obtain dbus address: %w", err)
-	}
-
-	fullFilename := "file://" + path.Join(dirname, filename)
-
-	log.Printf("Full filename: %s\n", fullFilename)
-
-	cmd := exec.Command("gsettings", "set", "org.gnome.desktop.background", "picture-uri", fullFilename)
-
-	env := os.Environ()
-	env = append(env, dbusAddress[:len(dbusAddress)-1])
-	cmd.Env = env // ensure we forward the environment to the new shell including the dbus address
-
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
-	err = cmd.Run()
-	if err != nil {
-		return fmt.Errorf("failed to set wallpaper: %w\n%s", err, out.String())
-	}
-	return nil
-}
-
-type fileSlice []os.FileInfo
-
-func (f fileSlice) Len() int           { return len(f) }
-func (f fileSlice) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
-func (f fileSlice) Less(i, j int) bool { return f[i].ModTime().Before(f[j].ModTime()) }
-
-func removeOldFiles(dirname string) error {
-	files, err := ioutil.ReadDir(dirname)
-	if err != nil {
-		return fmt.Errorf("Failed to read image directory: %v", err)
-	}
-
-	if len(files) < 10 {
-		log.Println("No images to delete")
-		return nil
-	}
-
-	sort.Sort(fileSlice(files))
-
-	filesToDelete := files[0 : len(files)-10]
-
-	for _, file := range filesToDelete {
-		filename := path.Join(dirname, file.Name())
-		log.Printf("Deleting image: %s\n", filename)
-
-		err = os.Remove(filename)
-		if err != nil {
-			return fmt.Errorf("Failed to delete image: %v", err)
-		}
-	}
-
-	return nil
-}
-
-func chooseImage(dirname string) (string, error) {
-	files, err := ioutil.ReadDir(dirname)
-	if err != nil {
-		return "", fmt.Errorf("Failed to read image directory: %v", err)
-	}
-
-	rand.Seed(time.Now().Unix())
-
-	return files[rand.Intn(len(files))].Name(), nil
-}
-
-func obtainDbusAddress() (string, error) {
-	currentUser, err := user.Current()
-	if err != nil {
-		return "", fmt.Errorf("Failed to obtain current user: %v", err)
-	}
-
-	var out bytes.Buffer
-
-	cmd := exec.Command("pgrep", "--euid", currentUser.Uid, "gnome-session")
-	cmd.Stdout = &out
-
-	err = cmd.Run()
-	if err != nil {
-		return "", fmt.Errorf("Failed to obtain gnome-session PID: %v", err)
-	}
-
-	pid := strings.TrimSpace(out.String())
-	out.Reset()
-
-	cmd = exec.Command("grep", "-z", "DBUS_SESSION_BUS_ADDRESS", fmt.Sprintf("/proc/%s/environ", pid))
-	cmd.Stdout = &out
-
-	err = cmd.Run()
-	if err != nil {
-		return "", fmt.Errorf("Failed to obtain dbus address: %v", err)
-	}
-
-	return out.String(), nil
-}
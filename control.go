@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+// listenControlSocket opens the Unix socket used to steer a running daemon
+// and starts accepting connections in the background.
+func (d *daemon) listenControlSocket(socketPath string) (net.Listener, error) {
+	// Remove a stale socket left behind by a daemon that didn't shut down
+	// cleanly; net.Listen refuses to bind over an existing file otherwise.
+	_ = os.Remove(socketPath)
+
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket %s: %w", socketPath, err)
+	}
+
+	go d.acceptControlConns(l)
+
+	return l, nil
+}
+
+func (d *daemon) acceptControlConns(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return // listener closed, daemon is shutting down
+		}
+
+		go d.handleControlConn(conn)
+	}
+}
+
+func (d *daemon) handleControlConn(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	if !scanner.Scan() {
+		return
+	}
+
+	cmd := strings.TrimSpace(scanner.Text())
+	ctx := context.Background()
+
+	var err error
+	switch cmd {
+	case "next":
+		err = d.navigate(ctx, 1)
+	case "prev":
+		err = d.navigate(ctx, -1)
+	case "now":
+		d.mu.Lock()
+		d.idx = 0
+		d.mu.Unlock()
+		err = d.refresh(ctx)
+	case "quit":
+		fmt.Fprintln(conn, "ok")
+		go os.Exit(0)
+		return
+	default:
+		err = fmt.Errorf("unknown command %q", cmd)
+	}
+
+	if err != nil {
+		fmt.Fprintf(conn, "error: %v\n", err)
+		return
+	}
+
+	fmt.Fprintln(conn, "ok")
+}
+
+// sendControlCommand connects to a running daemon's control socket, sends
+// cmd, and logs its response.
+func sendControlCommand(socketPath, cmd string) error {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to running daemon (is `bingdaily daemon` running?): %w", err)
+	}
+	defer conn.Close()
+
+	fmt.Fprintln(conn, cmd)
+
+	scanner := bufio.NewScanner(conn)
+	if scanner.Scan() {
+		resp := scanner.Text()
+		if strings.HasPrefix(resp, "error:") {
+			return errors.New(resp)
+		}
+
+		log.Println(resp)
+	}
+
+	return nil
+}
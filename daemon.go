@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+const controlSocketName = "control.sock"
+
+var daemonAtFlag = flag.String("at", "07:00", "local time of day (HH:MM) at which `daemon day` refreshes the wallpaper")
+
+// daemon keeps a bingdaily process running in the background, either
+// refreshing the wallpaper once a day or rotating already-downloaded images
+// on an interval, and exposes a Unix socket so a second invocation of the
+// binary can steer it (see control.go).
+type daemon struct {
+	mu        sync.Mutex
+	targetDir string
+	ws        WallpaperSetter
+	idx       int
+}
+
+// runDaemon starts a daemon in the given mode ("day" or "rand") and blocks
+// until it's asked to shut down via SIGINT/SIGTERM or the "quit" control
+// command.
+func runDaemon(mode string, args []string) error {
+	hd, err := homedir.Dir()
+	if err != nil {
+		return fmt.Errorf("failed to locate homedir: %w", err)
+	}
+
+	targetDir := path.Join(hd, imgDir)
+
+	err = os.MkdirAll(targetDir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to make image directory: %w", err)
+	}
+
+	ws, err := NewWallpaperSetter(*backendFlag)
+	if err != nil {
+		return fmt.Errorf("failed to select wallpaper backend: %w", err)
+	}
+
+	d := &daemon{targetDir: targetDir, ws: ws}
+
+	socketPath := path.Join(targetDir, controlSocketName)
+
+	listener, err := d.listenControlSocket(socketPath)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	defer os.Remove(socketPath)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		log.Println("Received shutdown signal, stopping daemon")
+		cancel()
+	}()
+
+	switch mode {
+	case "day":
+		return d.runDay(ctx, *daemonAtFlag)
+	case "rand":
+		if len(args) < 1 {
+			return fmt.Errorf("daemon rand requires a duration argument, e.g. `bingdaily daemon rand 1m`")
+		}
+
+		interval, err := time.ParseDuration(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", args[0], err)
+		}
+
+		return d.runRand(ctx, interval)
+	default:
+		return fmt.Errorf("unknown daemon mode %q (expected day or rand)", mode)
+	}
+}
+
+// runDay refreshes the wallpaper once per day, at the local time given by
+// at (format "HH:MM").
+func (d *daemon) runDay(ctx context.Context, at string) error {
+	atTime, err := time.Parse("15:04", at)
+	if err != nil {
+		return fmt.Errorf("invalid --at time %q: %w", at, err)
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	lastRun := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			today := now.Format("2006-01-02")
+			if now.Hour() == atTime.Hour() && now.Minute() == atTime.Minute() && lastRun != today {
+				lastRun = today
+
+				if err := d.refresh(ctx); err != nil {
+					log.Printf("failed to refresh wallpaper: %v\n", err)
+				}
+			}
+		}
+	}
+}
+
+// runRand rotates among already-downloaded images every interval, without
+// hitting the network.
+func (d *daemon) runRand(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := setWallpaper(d.targetDir, d.ws); err != nil {
+				log.Printf("failed to rotate wallpaper: %v\n", err)
+			}
+		}
+	}
+}
+
+// refresh downloads (if necessary) and sets the wallpaper currently at d's
+// archive index.
+func (d *daemon) refresh(ctx context.Context) error {
+	d.mu.Lock()
+	idx := d.idx
+	d.mu.Unlock()
+
+	im, err := downloadImage(ctx, d.targetDir, &bingSource{Idx: idx})
+	if err != nil {
+		return fmt.Errorf("failed to download image: %w", err)
+	}
+
+	return setWallpaperFile(path.Join(d.targetDir, im.Hash+"."+im.Extension), d.ws)
+}
+
+// navigate moves the archive index by delta (clamped to [0, bingMaxIdx])
+// and applies the resulting image as the wallpaper.
+func (d *daemon) navigate(ctx context.Context, delta int) error {
+	d.mu.Lock()
+	d.idx += delta
+	if d.idx < 0 {
+		d.idx = 0
+	}
+	if d.idx > bingMaxIdx {
+		d.idx = bingMaxIdx
+	}
+	d.mu.Unlock()
+
+	return d.refresh(ctx)
+}
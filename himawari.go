@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const sourceHimawari = "himawari"
+
+const (
+	himawariLatestURL = "https://himawari8-dl.nict.go.jp/himawari8/img/D531106/latest.json"
+	himawariTileURL   = "https://himawari8-dl.nict.go.jp/himawari8/img/D531106/%dd/550/%s_%d_%d.png"
+
+	// himawariLevel is the number of tiles per side of the stitched image.
+	// Level 4 gives a 4x4 grid of 550px tiles, i.e. a 2200x2200 composite.
+	himawariLevel   = 4
+	himawariTileDim = 550
+)
+
+type himawariLatest struct {
+	Date string `json:"date"`
+}
+
+// himawariSource stitches the Himawari-8 geostationary satellite's
+// level-4 earth-disk tiles into a single composite PNG. hourOffset looks
+// back in time (Himawari publishes a new image roughly every 10 minutes).
+type himawariSource struct {
+	hourOffset int
+}
+
+func (h *himawariSource) Fetch(ctx context.Context) (*imageMetadata, io.ReadCloser, error) {
+	ts, err := h.latestTimestamp(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to obtain latest timestamp: %w", err)
+	}
+
+	ts = ts.Add(-time.Duration(h.hourOffset) * time.Hour)
+	ts = ts.Truncate(10 * time.Minute)
+
+	buf, err := stitchHimawariComposite(ctx, ts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	im := &imageMetadata{
+		URL:       fmt.Sprintf(himawariTileURL, himawariLevel, ts.Format("2006/01/02/150405"), 0, 0),
+		Title:     fmt.Sprintf("Himawari-8 earth disk, %s UTC", ts.UTC().Format("2006-01-02 15:04")),
+		Hash:      himawariHash(ts),
+		Extension: "png",
+		Source:    sourceHimawari,
+	}
+
+	return im, ioutil.NopCloser(buf), nil
+}
+
+// himawariHash derives the history-ledger hash for the composite taken at
+// ts; parseHimawariHash reverses it so a composite can be re-stitched from
+// just its hash once the original file is gone.
+func himawariHash(ts time.Time) string {
+	return "himawari-" + ts.UTC().Format("20060102-150405")
+}
+
+func parseHimawariHash(hash string) (time.Time, error) {
+	const prefix = "himawari-"
+	if !strings.HasPrefix(hash, prefix) {
+		return time.Time{}, fmt.Errorf("not a himawari hash: %q", hash)
+	}
+
+	ts, err := time.Parse("20060102-150405", strings.TrimPrefix(hash, prefix))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse timestamp from hash %q: %w", hash, err)
+	}
+
+	return ts.UTC(), nil
+}
+
+// stitchHimawariComposite downloads and stitches the level-4 tile grid for
+// the exact timestamp ts into a single PNG.
+func stitchHimawariComposite(ctx context.Context, ts time.Time) (*bytes.Buffer, error) {
+	composite := image.NewRGBA(image.Rect(0, 0, himawariLevel*himawariTileDim, himawariLevel*himawariTileDim))
+
+	for x := 0; x < himawariLevel; x++ {
+		for y := 0; y < himawariLevel; y++ {
+			tile, err := fetchHimawariTile(ctx, ts, x, y)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch tile (%d,%d): %w", x, y, err)
+			}
+
+			dstRect := image.Rect(x*himawariTileDim, y*himawariTileDim, (x+1)*himawariTileDim, (y+1)*himawariTileDim)
+			draw.Draw(composite, dstRect, tile, image.Point{}, draw.Src)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, composite); err != nil {
+		return nil, fmt.Errorf("failed to encode composite image: %w", err)
+	}
+
+	return &buf, nil
+}
+
+// redownloadHimawari re-stitches the composite for a previously-recorded
+// hash and writes it to filename, for re-pinning a Himawari entry whose
+// image file has been deleted (the ledger's stored URL is only a single
+// tile, not enough on its own to reconstruct the composite).
+func redownloadHimawari(ctx context.Context, filename, hash string) error {
+	ts, err := parseHimawariHash(hash)
+	if err != nil {
+		return err
+	}
+
+	buf, err := stitchHimawariComposite(ctx, ts)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+func (h *himawariSource) latestTimestamp(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, himawariLatestURL, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to download latest.json: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var latest himawariLatest
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode JSON response: %w", err)
+	}
+
+	ts, err := time.Parse("2006-01-02 15:04:05", latest.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse latest timestamp %q: %w", latest.Date, err)
+	}
+
+	return ts, nil
+}
+
+func fetchHimawariTile(ctx context.Context, ts time.Time, x, y int) (image.Image, error) {
+	tileURL := fmt.Sprintf(himawariTileURL, himawariLevel, ts.Format("2006/01/02/150405"), x, y)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while downloading tile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response code: %s", resp.Status)
+	}
+
+	img, err := png.Decode(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tile PNG: %w", err)
+	}
+
+	return img, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	historyFileName   = "history.json"
+	historyMaxEntries = 100
+)
+
+// historyEntry is a single catalogued download. It outlives the image file
+// it describes: Show can re-download from URL if the file has since been
+// removed from disk.
+type historyEntry struct {
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	Hash         string    `json:"hash"`
+	Extension    string    `json:"extension"`
+	Copyright    string    `json:"copyright,omitempty"`
+	Source       string    `json:"source"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+// filename returns the basename this entry's image is (or was) stored
+// under.
+func (e historyEntry) filename() string {
+	return e.Hash + "." + e.Extension
+}
+
+type historyLedger struct {
+	Entries []historyEntry `json:"entries"`
+}
+
+func historyPath(targetDir string) string {
+	return path.Join(targetDir, historyFileName)
+}
+
+func loadHistory(targetDir string) (*historyLedger, error) {
+	b, err := ioutil.ReadFile(historyPath(targetDir))
+	if os.IsNotExist(err) {
+		return &historyLedger{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var h historyLedger
+	if err := json.Unmarshal(b, &h); err != nil {
+		return nil, fmt.Errorf("failed to parse history file: %w", err)
+	}
+
+	return &h, nil
+}
+
+func saveHistory(targetDir string, h *historyLedger) error {
+	b, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+
+	if err := ioutil.WriteFile(historyPath(targetDir), b, 0644); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	return nil
+}
+
+// recordHistory appends an entry for im to the ledger, rotating the ledger
+// out to a dated file first if it has reached historyMaxEntries.
+func recordHistory(targetDir string, im *imageMetadata) error {
+	h, err := loadHistory(targetDir)
+	if err != nil {
+		return err
+	}
+
+	if len(h.Entries) >= historyMaxEntries {
+		rotated, err := freeRotatedHistoryPath(targetDir)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Rename(historyPath(targetDir), rotated); err != nil {
+			return fmt.Errorf("failed to rotate history file: %w", err)
+		}
+
+		log.Printf("Rotated history file to %s\n", rotated)
+		h = &historyLedger{}
+	}
+
+	h.Entries = append(h.Entries, historyEntry{
+		Title:        im.Title,
+		URL:          im.URL,
+		Hash:         im.Hash,
+		Extension:    im.Extension,
+		Copyright:    im.Copyright,
+		Source:       im.Source,
+		DownloadedAt: time.Now(),
+	})
+
+	return saveHistory(targetDir, h)
+}
+
+// freeRotatedHistoryPath returns a history-<timestamp>.json path that
+// doesn't collide with an existing file. The timestamp includes
+// second-resolution time, not just the date, so two rotations on the same
+// day don't overwrite one another; a numeric suffix is appended on the
+// rare chance even that isn't enough (e.g. two rotations within the same
+// second).
+func freeRotatedHistoryPath(targetDir string) (string, error) {
+	base := fmt.Sprintf("history-%s", time.Now().Format("20060102-150405"))
+
+	candidate := path.Join(targetDir, base+".json")
+	for i := 1; ; i++ {
+		exists, err := imageExists(candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check rotated history path: %w", err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		candidate = path.Join(targetDir, fmt.Sprintf("%s-%d.json", base, i))
+	}
+}
+
+// allHistoryEntries reads both the current ledger and any rotated
+// history-*.json files, newest first.
+func allHistoryEntries(targetDir string) ([]historyEntry, error) {
+	current, err := loadHistory(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := append([]historyEntry{}, current.Entries...)
+
+	matches, err := filepath.Glob(path.Join(targetDir, "history-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotated history files: %w", err)
+	}
+
+	for _, m := range matches {
+		b, err := ioutil.ReadFile(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rotated history file %s: %w", m, err)
+		}
+
+		var h historyLedger
+		if err := json.Unmarshal(b, &h); err != nil {
+			return nil, fmt.Errorf("failed to parse rotated history file %s: %w", m, err)
+		}
+
+		entries = append(entries, h.Entries...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DownloadedAt.After(entries[j].DownloadedAt) })
+
+	return entries, nil
+}
+
+// chooseImage picks a random history entry whose backing file still exists
+// on disk in dirname, falling back to a plain directory listing if the
+// ledger is empty or unreadable.
+func chooseImage(dirname string) (string, error) {
+	entries, err := allHistoryEntries(dirname)
+	if err == nil {
+		var present []historyEntry
+		for _, e := range entries {
+			if exists, _ := imageExists(path.Join(dirname, e.filename())); exists {
+				present = append(present, e)
+			}
+		}
+
+		if len(present) > 0 {
+			rand.Seed(time.Now().Unix())
+			return present[rand.Intn(len(present))].filename(), nil
+		}
+	}
+
+	files, err := ioutil.ReadDir(dirname)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image directory: %w", err)
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no images found in %s", dirname)
+	}
+
+	rand.Seed(time.Now().Unix())
+
+	return files[rand.Intn(len(files))].Name(), nil
+}
+
+// findHistoryEntry looks up the entry with the given hash across the
+// current and rotated ledgers.
+func findHistoryEntry(targetDir, hash string) (*historyEntry, error) {
+	entries, err := allHistoryEntries(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.Hash == hash {
+			return &e, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no history entry found for hash %q", hash)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// runList implements `bingdaily list [--source=bing] [--limit=20]`,
+// printing the history ledger newest-first.
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	source := fs.String("source", "", "only show entries from this source")
+	limit := fs.Int("limit", 20, "maximum number of entries to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	targetDir, err := defaultTargetDir()
+	if err != nil {
+		return err
+	}
+
+	entries, err := allHistoryEntries(targetDir)
+	if err != nil {
+		return err
+	}
+
+	shown := 0
+	for _, e := range entries {
+		if shown >= *limit {
+			break
+		}
+		if *source != "" && e.Source != *source {
+			continue
+		}
+
+		fmt.Printf("%s  %-10s %-20s %s\n", e.DownloadedAt.Format("2006-01-02 15:04"), e.Source, e.Hash, e.Title)
+		shown++
+	}
+
+	return nil
+}
+
+// runShow implements `bingdaily show <hash>`, re-setting a previously
+// downloaded image as the current wallpaper. If the file has since been
+// deleted from disk, it's re-downloaded from the URL recorded in the
+// history ledger.
+func runShow(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("show requires exactly one argument: the image hash")
+	}
+	hash := args[0]
+
+	targetDir, err := defaultTargetDir()
+	if err != nil {
+		return err
+	}
+
+	entry, err := findHistoryEntry(targetDir, hash)
+	if err != nil {
+		return err
+	}
+
+	filename := path.Join(targetDir, entry.filename())
+
+	exists, err := imageExists(filename)
+	if err != nil {
+		return fmt.Errorf("unable to determine whether file exists: %w", err)
+	}
+
+	if !exists {
+		log.Printf("Image for %s no longer on disk, re-downloading\n", hash)
+		if err := redownloadEntry(context.Background(), filename, entry); err != nil {
+			return err
+		}
+	}
+
+	ws, err := NewWallpaperSetter(*backendFlag)
+	if err != nil {
+		return fmt.Errorf("failed to select wallpaper backend: %w", err)
+	}
+
+	return setWallpaperFile(filename, ws)
+}
+
+func defaultTargetDir() (string, error) {
+	hd, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate homedir: %w", err)
+	}
+
+	return path.Join(hd, imgDir), nil
+}
+
+// redownloadEntry re-fetches the image backing entry when its file has
+// been deleted from disk. Most sources just re-fetch entry.URL, but
+// sources whose URL doesn't fully describe the downloaded image (e.g.
+// Himawari, whose composite is stitched from several tiles) need their own
+// reconstruction logic.
+func redownloadEntry(ctx context.Context, filename string, entry *historyEntry) error {
+	if entry.Source == sourceHimawari {
+		return redownloadHimawari(ctx, filename, entry.Hash)
+	}
+
+	resp, err := http.Get(entry.URL)
+	if err != nil {
+		return fmt.Errorf("failed to re-download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response code: %s", resp.Status)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return fmt.Errorf("failed to write image to output file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const sourceLocal = "local"
+
+// localDirSource picks a random image file out of a local directory,
+// useful for cycling through a curated folder of wallpapers that didn't
+// come from any online source.
+type localDirSource struct {
+	dir string
+}
+
+func (l *localDirSource) Fetch(ctx context.Context) (*imageMetadata, io.ReadCloser, error) {
+	entries, err := ioutil.ReadDir(l.dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read local image directory: %w", err)
+	}
+
+	var files []os.FileInfo
+	for _, e := range entries {
+		if !e.IsDir() {
+			files = append(files, e)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no files found in local image directory: %s", l.dir)
+	}
+
+	rand.Seed(time.Now().Unix())
+	chosen := files[rand.Intn(len(files))]
+
+	f, err := os.Open(path.Join(l.dir, chosen.Name()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open local image: %w", err)
+	}
+
+	ext := filepath.Ext(chosen.Name())
+	im := &imageMetadata{
+		URL:       "file://" + path.Join(l.dir, chosen.Name()),
+		Title:     chosen.Name(),
+		Hash:      "local-" + chosen.Name(),
+		Extension: trimLeadingDot(ext, "jpg"),
+		Source:    sourceLocal,
+	}
+
+	return im, f, nil
+}
+
+func trimLeadingDot(ext, def string) string {
+	if ext == "" {
+		return def
+	}
+	return ext[1:]
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+//go:embed assets
+var galleryAssets embed.FS
+
+const thumbWidth = 320
+
+var (
+	serveFlag     = flag.String("serve", "", "address to serve the HTTP API and web gallery on, e.g. :8080 (disabled if empty)")
+	authTokenFlag = flag.String("auth-token", "", "if set, require this token (Bearer header or HTTP basic auth password) for all API/gallery requests")
+)
+
+// server exposes the history ledger and current archive position over
+// HTTP: a small REST API plus a static gallery that consumes it.
+type server struct {
+	targetDir string
+	ws        WallpaperSetter
+	auth      authFunc
+
+	mu  sync.Mutex
+	idx int
+}
+
+// runServer starts the HTTP API and gallery and blocks serving requests.
+func runServer(addr string) error {
+	targetDir, err := defaultTargetDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return fmt.Errorf("failed to make image directory: %w", err)
+	}
+
+	ws, err := NewWallpaperSetter(*backendFlag)
+	if err != nil {
+		return fmt.Errorf("failed to select wallpaper backend: %w", err)
+	}
+
+	s := &server{targetDir: targetDir, ws: ws, auth: tokenAuth(*authTokenFlag)}
+
+	assets, err := fs.Sub(galleryAssets, "assets")
+	if err != nil {
+		return fmt.Errorf("failed to load embedded gallery assets: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/images", requireAuth(s.auth, s.handleListImages))
+	mux.HandleFunc("/api/images/", requireAuth(s.auth, s.handleGetImage))
+	mux.HandleFunc("/api/wallpaper/", requireAuth(s.auth, s.handleSetWallpaper))
+	mux.HandleFunc("/api/next", requireAuth(s.auth, s.handleNext))
+	mux.HandleFunc("/api/prev", requireAuth(s.auth, s.handlePrev))
+	mux.HandleFunc("/thumb/", requireAuth(s.auth, s.handleThumb))
+	mux.HandleFunc("/", requireAuth(s.auth, http.FileServer(http.FS(assets)).ServeHTTP))
+
+	log.Printf("Serving bingdaily API and gallery on %s\n", addr)
+
+	return http.ListenAndServe(addr, mux)
+}
+
+func (s *server) handleListImages(w http.ResponseWriter, r *http.Request) {
+	entries, err := allHistoryEntries(s.targetDir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, entries)
+}
+
+func (s *server) handleGetImage(w http.ResponseWriter, r *http.Request) {
+	hash := strings.TrimPrefix(r.URL.Path, "/api/images/")
+
+	entry, err := findHistoryEntry(s.targetDir, hash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, entry)
+}
+
+func (s *server) handleSetWallpaper(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hash := strings.TrimPrefix(r.URL.Path, "/api/wallpaper/")
+
+	entry, err := findHistoryEntry(s.targetDir, hash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	filename := path.Join(s.targetDir, entry.filename())
+
+	exists, err := imageExists(filename)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !exists {
+		if err := redownloadEntry(r.Context(), filename, entry); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := setWallpaperFile(filename, s.ws); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, entry)
+}
+
+func (s *server) handleNext(w http.ResponseWriter, r *http.Request) {
+	s.navigate(w, r, 1)
+}
+
+func (s *server) handlePrev(w http.ResponseWriter, r *http.Request) {
+	s.navigate(w, r, -1)
+}
+
+// navigate moves the archive index by delta (clamped to [0, bingMaxIdx]),
+// downloads and sets the resulting Bing image, and reports its metadata.
+func (s *server) navigate(w http.ResponseWriter, r *http.Request, delta int) {
+	s.mu.Lock()
+	s.idx += delta
+	if s.idx < 0 {
+		s.idx = 0
+	}
+	if s.idx > bingMaxIdx {
+		s.idx = bingMaxIdx
+	}
+	idx := s.idx
+	s.mu.Unlock()
+
+	im, err := downloadImage(r.Context(), s.targetDir, &bingSource{Idx: idx})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := setWallpaperFile(path.Join(s.targetDir, im.Hash+"."+im.Extension), s.ws); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, im)
+}
+
+// handleThumb serves an on-the-fly resized thumbnail of the image with the
+// given hash.
+func (s *server) handleThumb(w http.ResponseWriter, r *http.Request) {
+	hash := strings.TrimPrefix(r.URL.Path, "/thumb/")
+
+	entry, err := findHistoryEntry(s.targetDir, hash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	img, err := imaging.Open(path.Join(s.targetDir, entry.filename()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	thumb := imaging.Resize(img, thumbWidth, 0, imaging.Lanczos)
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	if err := imaging.Encode(w, thumb, imaging.JPEG); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write JSON response: %v\n", err)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+)
+
+// ImageSource is implemented by anything that can hand us the metadata and
+// raw bytes of a wallpaper image. Each source is responsible for its own
+// hash/filename scheme, since "hash" may mean a Bing image ID, a NASA APOD
+// date, an Unsplash photo ID, or a local file's basename.
+type ImageSource interface {
+	Fetch(ctx context.Context) (*imageMetadata, io.ReadCloser, error)
+}
+
+const sourceRandom = "random"
+
+// availableSources lists the source names that --source=random may pick
+// between. It intentionally excludes "local", since a local directory is
+// only useful when explicitly requested.
+var availableSources = []string{sourceBing, sourceAPOD, sourceUnsplash, sourceHimawari}
+
+// newImageSource builds the ImageSource named by the --source flag, using
+// the other source-specific flags for configuration. name == "random"
+// chooses uniformly among availableSources.
+func newImageSource(name string) (ImageSource, error) {
+	if name == sourceRandom {
+		name = availableSources[rand.Intn(len(availableSources))]
+		log.Printf("Randomly selected image source: %s\n", name)
+	}
+
+	switch name {
+	case sourceBing:
+		return &bingSource{}, nil
+	case sourceAPOD:
+		return &apodSource{apiKey: *apodKeyFlag}, nil
+	case sourceUnsplash:
+		return &unsplashSource{accessKey: *unsplashKeyFlag}, nil
+	case sourceHimawari:
+		return &himawariSource{hourOffset: *himawariOffsetFlag}, nil
+	case sourceLocal:
+		if *localDirFlag == "" {
+			return nil, fmt.Errorf("--local-dir must be set when using the local image source")
+		}
+		return &localDirSource{dir: *localDirFlag}, nil
+	default:
+		return nil, fmt.Errorf("unknown image source %q", name)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const sourceUnsplash = "unsplash"
+
+const unsplashRandomURL = "https://api.unsplash.com/photos/random"
+
+type unsplashResponse struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	AltDesc     string `json:"alt_description"`
+	URLs        struct {
+		Full string `json:"full"`
+	} `json:"urls"`
+}
+
+// unsplashSource fetches a random photo from Unsplash's public API.
+type unsplashSource struct {
+	accessKey string
+}
+
+func (u *unsplashSource) Fetch(ctx context.Context) (*imageMetadata, io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, unsplashRandomURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Client-ID "+u.accessKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected response code: %s", resp.Status)
+	}
+
+	var ur unsplashResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ur); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode JSON response: %w", err)
+	}
+
+	title := ur.Description
+	if title == "" {
+		title = ur.AltDesc
+	}
+
+	im := &imageMetadata{
+		URL:       ur.URLs.Full,
+		Title:     title,
+		Hash:      "unsplash-" + ur.ID,
+		Extension: "jpg",
+		Source:    sourceUnsplash,
+	}
+
+	imgReq, err := http.NewRequestWithContext(ctx, http.MethodGet, im.URL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	imgResp, err := http.DefaultClient.Do(imgReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while downloading image: %w", err)
+	}
+
+	if imgResp.StatusCode != http.StatusOK {
+		imgResp.Body.Close()
+		return nil, nil, fmt.Errorf("unexpected response code: %s", imgResp.Status)
+	}
+
+	return im, imgResp.Body, nil
+}
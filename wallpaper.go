@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path"
+)
+
+// WallpaperSetter is implemented once per supported platform/desktop and is
+// responsible for actually pushing an image path to the OS as the desktop
+// background.
+type WallpaperSetter interface {
+	SetWallpaper(filename string) error
+}
+
+// setWallpaper picks a random already-downloaded image from dirname's
+// history catalog and hands it off to ws to be applied as the desktop
+// background.
+func setWallpaper(dirname string, ws WallpaperSetter) error {
+	filename, err := chooseImage(dirname)
+	if err != nil {
+		return fmt.Errorf("failed to choose an image: %w", err)
+	}
+
+	return setWallpaperFile(path.Join(dirname, filename), ws)
+}
+
+// setWallpaperFile applies the image at the given path as the desktop
+// background.
+func setWallpaperFile(filename string, ws WallpaperSetter) error {
+	fullFilename := "file://" + filename
+
+	log.Printf("Full filename: %s\n", fullFilename)
+
+	return ws.SetWallpaper(fullFilename)
+}
@@ -0,0 +1,43 @@
+//go:build darwin
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// darwinWallpaperSetter shells out to osascript to ask System Events to set
+// the desktop picture. There is only one backend on macOS, so the --backend
+// flag accepts only "macos" (or empty); any other value is an error.
+type darwinWallpaperSetter struct{}
+
+// NewWallpaperSetter builds the platform-appropriate WallpaperSetter.
+func NewWallpaperSetter(backend string) (WallpaperSetter, error) {
+	if backend != "" && backend != "macos" {
+		return nil, fmt.Errorf("unsupported backend %q for darwin", backend)
+	}
+
+	return &darwinWallpaperSetter{}, nil
+}
+
+func (d *darwinWallpaperSetter) SetWallpaper(filename string) error {
+	path := strings.TrimPrefix(filename, "file://")
+
+	script := fmt.Sprintf(`tell application "System Events" to tell every desktop to set picture to %q`, path)
+
+	cmd := exec.Command("osascript", "-e", script)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("failed to set wallpaper: %w\n%s", err, out.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+//go:build linux
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+)
+
+// desktopBackend identifies one of the Linux desktop environments we know
+// how to set a wallpaper under.
+type desktopBackend string
+
+const (
+	backendGNOME desktopBackend = "gnome"
+	backendKDE   desktopBackend = "kde"
+	backendXFCE  desktopBackend = "xfce"
+	backendSway  desktopBackend = "sway"
+)
+
+// linuxWallpaperSetter dispatches to the right desktop-specific command
+// based on either an explicit backend override or a sniff of
+// $XDG_CURRENT_DESKTOP / $WAYLAND_DISPLAY.
+type linuxWallpaperSetter struct {
+	backend desktopBackend
+
+	// swaybgProc is the previously-started swaybg process, if any. swaybg
+	// stays in the foreground to keep displaying the wallpaper, so unlike
+	// the other backends it can't be run with cmd.Run() and needs the
+	// prior instance killed off when a new one replaces it.
+	swaybgProc *os.Process
+}
+
+// NewWallpaperSetter builds the platform-appropriate WallpaperSetter. An
+// empty backend means "detect automatically"; otherwise backend must be one
+// of gnome, kde, xfce or sway and is used as-is, letting the --backend flag
+// override detection.
+func NewWallpaperSetter(backend string) (WallpaperSetter, error) {
+	if backend == "" {
+		return &linuxWallpaperSetter{backend: detectDesktop()}, nil
+	}
+
+	b := desktopBackend(backend)
+	switch b {
+	case backendGNOME, backendKDE, backendXFCE, backendSway:
+		return &linuxWallpaperSetter{backend: b}, nil
+	default:
+		return nil, fmt.Errorf("unsupported backend %q for linux", backend)
+	}
+}
+
+// detectDesktop sniffs the running desktop environment from the
+// environment, falling back to sway/swaybg when only a Wayland display is
+// present, and to GNOME otherwise.
+func detectDesktop() desktopBackend {
+	switch strings.ToLower(os.Getenv("XDG_CURRENT_DESKTOP")) {
+	case "gnome":
+		return backendGNOME
+	case "kde":
+		return backendKDE
+	case "xfce":
+		return backendXFCE
+	case "sway":
+		return backendSway
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return backendSway
+	}
+
+	return backendGNOME
+}
+
+func (l *linuxWallpaperSetter) SetWallpaper(filename string) error {
+	switch l.backend {
+	case backendKDE:
+		return setWallpaperKDE(filename)
+	case backendXFCE:
+		return setWallpaperXFCE(filename)
+	case backendSway:
+		return l.setWallpaperSway(filename)
+	default:
+		return setWallpaperGNOME(filename)
+	}
+}
+
+func setWallpaperGNOME(filename string) error {
+	dbusAddress, err := obtainDbusAddress()
+	if err != nil {
+		return fmt.Errorf("failed to obtain dbus address: %w", err)
+	}
+
+	cmd := exec.Command("gsettings", "set", "org.gnome.desktop.background", "picture-uri", filename)
+
+	env := os.Environ()
+	env = append(env, dbusAddress[:len(dbusAddress)-1])
+	cmd.Env = env // ensure we forward the environment to the new shell including the dbus address
+
+	return runCommand(cmd)
+}
+
+func setWallpaperKDE(filename string) error {
+	script := fmt.Sprintf(`
+		var allDesktops = desktops();
+		for (i=0;i<allDesktops.length;i++) {
+			d = allDesktops[i];
+			d.wallpaperPlugin = "org.kde.image";
+			d.currentConfigGroup = Array("Wallpaper", "org.kde.image", "General");
+			d.writeConfig("Image", %q);
+		}`, filename)
+
+	cmd := exec.Command("qdbus", "org.kde.plasmashell", "/PlasmaShell", "org.kde.PlasmaShell.evaluateScript", script)
+
+	return runCommand(cmd)
+}
+
+func setWallpaperXFCE(filename string) error {
+	cmd := exec.Command("xfconf-query", "-c", "xfce4-desktop", "-p", "/backdrop/screen0/monitor0/workspace0/last-image", "-s", strings.TrimPrefix(filename, "file://"))
+
+	return runCommand(cmd)
+}
+
+// setWallpaperSway starts a new swaybg instance showing filename and kills
+// whichever instance it previously started. swaybg is meant to stay
+// running in the foreground to keep the wallpaper displayed, so it must be
+// started detached rather than run to completion.
+func (l *linuxWallpaperSetter) setWallpaperSway(filename string) error {
+	cmd := exec.Command("swaybg", "-i", strings.TrimPrefix(filename, "file://"), "-m", "fill")
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start swaybg: %w\n%s", err, out.String())
+	}
+
+	// Reap the process in the background instead of Wait()-ing on it here,
+	// since we don't want to block the caller.
+	go cmd.Wait()
+
+	if l.swaybgProc != nil {
+		_ = l.swaybgProc.Kill()
+	}
+	l.swaybgProc = cmd.Process
+
+	return nil
+}
+
+func runCommand(cmd *exec.Cmd) error {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("failed to set wallpaper: %w\n%s", err, out.String())
+	}
+
+	return nil
+}
+
+func obtainDbusAddress() (string, error) {
+	currentUser, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain current user: %v", err)
+	}
+
+	var out bytes.Buffer
+
+	cmd := exec.Command("pgrep", "--euid", currentUser.Uid, "gnome-session")
+	cmd.Stdout = &out
+
+	err = cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain gnome-session PID: %v", err)
+	}
+
+	pid := strings.TrimSpace(out.String())
+	out.Reset()
+
+	cmd = exec.Command("grep", "-z", "DBUS_SESSION_BUS_ADDRESS", fmt.Sprintf("/proc/%s/environ", pid))
+	cmd.Stdout = &out
+
+	err = cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain dbus address: %v", err)
+	}
+
+	return out.String(), nil
+}
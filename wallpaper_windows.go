@@ -0,0 +1,54 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	spiSetDeskWallpaper = 0x0014
+	spifUpdateIniFile   = 0x01
+	spifSendChange      = 0x02
+)
+
+// windowsWallpaperSetter calls SystemParametersInfoW via user32.dll. There is
+// only one backend on Windows, so the --backend flag accepts only "windows"
+// (or empty); any other value is an error.
+type windowsWallpaperSetter struct{}
+
+// NewWallpaperSetter builds the platform-appropriate WallpaperSetter.
+func NewWallpaperSetter(backend string) (WallpaperSetter, error) {
+	if backend != "" && backend != "windows" {
+		return nil, fmt.Errorf("unsupported backend %q for windows", backend)
+	}
+
+	return &windowsWallpaperSetter{}, nil
+}
+
+func (w *windowsWallpaperSetter) SetWallpaper(filename string) error {
+	path := strings.TrimPrefix(filename, "file://")
+
+	user32 := syscall.NewLazyDLL("user32.dll")
+	systemParametersInfoW := user32.NewProc("SystemParametersInfoW")
+
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return fmt.Errorf("failed to convert path to UTF16: %w", err)
+	}
+
+	ret, _, err := systemParametersInfoW.Call(
+		uintptr(spiSetDeskWallpaper),
+		0,
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(spifUpdateIniFile|spifSendChange),
+	)
+	if ret == 0 {
+		return fmt.Errorf("SystemParametersInfoW failed: %w", err)
+	}
+
+	return nil
+}